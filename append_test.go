@@ -0,0 +1,145 @@
+package bfh
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAppendEncodePreservesPrefix(t *testing.T) {
+	for _, in := range testPayloads() {
+		prefix := []byte("prefix:")
+
+		got := AppendEncode(append([]byte(nil), prefix...), in)
+		if !bytes.HasPrefix(got, prefix) {
+			t.Fatalf("AppendEncode(%v, %v) = %q, want it to keep the %q prefix", prefix, in, got, prefix)
+		}
+
+		want, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		if string(got[len(prefix):]) != want {
+			t.Errorf("AppendEncode(%v, %v) = %q, want %q appended", prefix, in, got[len(prefix):], want)
+		}
+	}
+}
+
+func TestAppendDecodePreservesPrefix(t *testing.T) {
+	for _, in := range testPayloads() {
+		str, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		prefix := []byte{0xff, 0xee}
+
+		got, err := AppendDecode(append([]byte(nil), prefix...), []byte(str))
+		if err != nil {
+			t.Fatalf("AppendDecode(%q) returned error: %v", str, err)
+		}
+
+		if !bytes.HasPrefix(got, prefix) {
+			t.Fatalf("AppendDecode(%v, %q) = %v, want it to keep the prefix", prefix, str, got)
+		}
+
+		if !bytes.Equal(got[len(prefix):], in) {
+			t.Errorf("AppendDecode(%v, %q) = %v, want %v appended", prefix, str, got[len(prefix):], in)
+		}
+	}
+}
+
+func TestEncodedLenMatchesOutput(t *testing.T) {
+	for _, in := range testPayloads() {
+		str, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		if got, want := EncodedLen(len(in)), len(str); got != want {
+			t.Errorf("EncodedLen(%d) = %d, want %d (len of %q)", len(in), got, want, str)
+		}
+	}
+}
+
+func TestDecodedLenFormula(t *testing.T) {
+	for n := 0; n <= 40; n++ {
+		if got, want := DecodedLen(n), n*5/8; got != want {
+			t.Errorf("DecodedLen(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func benchmarkPayload(b *testing.B, size int) []byte {
+	b.Helper()
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	return data
+}
+
+const benchmarkPayloadSize = 1 << 20 // 1 MiB
+
+func BenchmarkEncode(b *testing.B) {
+	data := benchmarkPayload(b, benchmarkPayloadSize)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Encode(data); err != nil {
+			b.Fatalf("Encode returned error: %v", err)
+		}
+	}
+}
+
+func BenchmarkAppendEncode(b *testing.B) {
+	data := benchmarkPayload(b, benchmarkPayloadSize)
+	dst := make([]byte, 0, EncodedLen(len(data)))
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		AppendEncode(dst[:0], data)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := benchmarkPayload(b, benchmarkPayloadSize)
+
+	str, err := Encode(data)
+	if err != nil {
+		b.Fatalf("Encode returned error: %v", err)
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(str); err != nil {
+			b.Fatalf("Decode returned error: %v", err)
+		}
+	}
+}
+
+func BenchmarkAppendDecode(b *testing.B) {
+	data := benchmarkPayload(b, benchmarkPayloadSize)
+
+	str, err := Encode(data)
+	if err != nil {
+		b.Fatalf("Encode returned error: %v", err)
+	}
+
+	src := []byte(str)
+	dst := make([]byte, 0, DecodedLen(len(src)))
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := AppendDecode(dst[:0], src); err != nil {
+			b.Fatalf("AppendDecode returned error: %v", err)
+		}
+	}
+}
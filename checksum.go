@@ -0,0 +1,85 @@
+package bfh
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// crockfordCheckAlphabet extends the standard Crockford digits with the
+// five symbols reserved for the optional check character, in value order:
+// index i is the symbol for checksum value i.
+const crockfordCheckAlphabet = digits + "*~$=U"
+
+// ErrChecksumMismatch is returned by DecodeWithCheck when the trailing check
+// symbol doesn't match the checksum computed from the decoded data.
+var ErrChecksumMismatch = errors.New("bfh: checksum mismatch")
+
+// ErrMissingCheckSymbol is returned by DecodeWithCheck when str doesn't end
+// in a single check symbol after the last separator.
+var ErrMissingCheckSymbol = errors.New("bfh: string does not end in a check symbol")
+
+// EncodeWithCheck encodes b with StdEncoding and appends a Crockford Base32
+// check symbol, computed by treating b as a big-endian unsigned integer and
+// taking the remainder modulo 37, after the last group (e.g. "1-abcd-efgh-U").
+//
+// This is only meaningful for StdEncoding's Crockford alphabet, so unlike
+// Encode it isn't exposed as an Encoding method.
+func EncodeWithCheck(b []byte) (string, error) {
+	str, err := Encode(b)
+	if err != nil {
+		return "", err
+	}
+
+	c := crockfordChecksum(b)
+
+	return str + string(StdEncoding.separator) + crockfordCheckAlphabet[c:c+1], nil
+}
+
+// DecodeWithCheck decodes str, which must end in a check symbol appended by
+// EncodeWithCheck, and returns ErrChecksumMismatch if the symbol doesn't
+// match the checksum of the decoded data. As in Crockford's spec, I, L and O
+// are accepted as aliases for 1, 1 and 0.
+func DecodeWithCheck(str string) ([]byte, error) {
+	str = normalizeCrockford(str)
+
+	sep := string(StdEncoding.separator)
+
+	i := strings.LastIndex(str, sep)
+	if i < 0 || i != len(str)-2 {
+		return nil, ErrMissingCheckSymbol
+	}
+
+	body, checkChar := str[:i], str[i+1:]
+
+	data, err := Decode(body)
+	if err != nil {
+		return nil, err
+	}
+
+	want := strings.Index(crockfordCheckAlphabet, checkChar)
+	if want < 0 {
+		return nil, fmt.Errorf(errMsgContainsInvalidCharacter, checkChar)
+	}
+
+	if uint64(want) != crockfordChecksum(data) {
+		return nil, ErrChecksumMismatch
+	}
+
+	return data, nil
+}
+
+func crockfordChecksum(b []byte) uint64 {
+	return new(big.Int).Mod(new(big.Int).SetBytes(b), big.NewInt(37)).Uint64()
+}
+
+// normalizeCrockford rewrites the ambiguous letters Crockford's spec allows
+// as aliases (I, L and O) to the digits they're easily confused with.
+func normalizeCrockford(str string) string {
+	return strings.NewReplacer(
+		"I", "1", "i", "1",
+		"L", "1", "l", "1",
+		"O", "0", "o", "0",
+	).Replace(str)
+}
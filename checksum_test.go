@@ -0,0 +1,98 @@
+package bfh
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecodeWithCheckRoundTrip(t *testing.T) {
+	for _, in := range testPayloads() {
+		str, err := EncodeWithCheck(in)
+		if err != nil {
+			t.Fatalf("EncodeWithCheck(%v) returned error: %v", in, err)
+		}
+
+		out, err := DecodeWithCheck(str)
+		if err != nil {
+			t.Fatalf("DecodeWithCheck(%q) returned error: %v", str, err)
+		}
+
+		if !bytes.Equal(in, out) {
+			t.Errorf("round trip mismatch: DecodeWithCheck(EncodeWithCheck(%v)) = %v", in, out)
+		}
+	}
+}
+
+func TestDecodeWithCheckAcceptsAliases(t *testing.T) {
+	str, err := EncodeWithCheck([]byte{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("EncodeWithCheck returned error: %v", err)
+	}
+
+	aliased := strings.NewReplacer("0", "O", "1", "I").Replace(str)
+	if aliased == str {
+		t.Skip("check string contains no aliasable characters")
+	}
+
+	out, err := DecodeWithCheck(aliased)
+	if err != nil {
+		t.Fatalf("DecodeWithCheck(%q) returned error: %v", aliased, err)
+	}
+
+	want, err := DecodeWithCheck(str)
+	if err != nil {
+		t.Fatalf("DecodeWithCheck(%q) returned error: %v", str, err)
+	}
+
+	if !bytes.Equal(want, out) {
+		t.Errorf("DecodeWithCheck(%q) = %v, want %v", aliased, out, want)
+	}
+}
+
+func TestDecodeWithCheckMismatch(t *testing.T) {
+	str, err := EncodeWithCheck([]byte{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("EncodeWithCheck returned error: %v", err)
+	}
+
+	corrupted := str[:len(str)-1] + flipCheckChar(str[len(str)-1])
+
+	_, err = DecodeWithCheck(corrupted)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("DecodeWithCheck(%q) returned error %v, want ErrChecksumMismatch", corrupted, err)
+	}
+}
+
+func flipCheckChar(c byte) string {
+	for _, r := range crockfordCheckAlphabet {
+		if byte(r) != c {
+			return string(r)
+		}
+	}
+
+	return string(c)
+}
+
+func TestDecodeWithCheckMissingSymbol(t *testing.T) {
+	for _, str := range []string{"", "1-abcd", "1-abcd-"} {
+		_, err := DecodeWithCheck(str)
+		if !errors.Is(err, ErrMissingCheckSymbol) {
+			t.Errorf("DecodeWithCheck(%q) returned error %v, want ErrMissingCheckSymbol", str, err)
+		}
+	}
+}
+
+func TestDecodeWithCheckInvalidSymbol(t *testing.T) {
+	str, err := EncodeWithCheck([]byte{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("EncodeWithCheck returned error: %v", err)
+	}
+
+	corrupted := str[:len(str)-1] + "#"
+
+	if _, err := DecodeWithCheck(corrupted); err == nil {
+		t.Errorf("DecodeWithCheck(%q) returned no error", corrupted)
+	}
+}
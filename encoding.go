@@ -0,0 +1,533 @@
+package bfh
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Encoding is a configurable bytes-for-humans codec, analogous to
+// base32.Encoding and base64.Encoding. The zero value is not usable;
+// construct one with NewEncoding.
+type Encoding struct {
+	alphabet  string
+	separator rune
+	groupSize int
+	strict    bool
+
+	bitsPerChar uint
+	blockBytes  int // bytes per full zero-padding block: lcm(bitsPerChar, 8) / 8
+	blockChars  int // characters per full block: lcm(bitsPerChar, 8) / bitsPerChar
+
+	digitMap    map[string]uint8
+	encodeMasks []uint8 // encodeMasks[m] masks off the m high bits already consumed from a byte
+	decodeMasks []uint8 // decodeMasks[n-1] masks the low n bits of a decoded character value
+
+	padding *paddingCache
+
+	standardRegex   *regexp.Regexp
+	acceptableRegex *regexp.Regexp
+	strictRegex     *regexp.Regexp
+}
+
+// paddingCache holds the lazily computed, per-padding-length sets of
+// characters that are valid immediately before the trailing run of zero
+// characters. It's held behind a pointer and its own mutex so that Encoding
+// values can be copied (by With* methods) without copying a lock.
+type paddingCache struct {
+	mu   sync.Mutex
+	sets map[int]map[string]struct{}
+}
+
+// NewEncoding returns a new Encoding defined by alphabet, which must have a
+// power-of-two length of at least 2 so that every character maps to a whole number of
+// bits, mirroring the constraints of base32.NewEncoding and
+// base64.NewEncoding. It panics if alphabet does not satisfy that
+// constraint. The returned Encoding uses a hyphen separator and groups of 4
+// characters by default; use WithSeparator and WithGroupSize to change
+// either.
+func NewEncoding(alphabet string) *Encoding {
+	n := len(alphabet)
+	if n < 2 || n&(n-1) != 0 {
+		panic("bfh: encoding alphabet length must be a power of two")
+	}
+
+	e := &Encoding{
+		alphabet:    alphabet,
+		separator:   '-',
+		groupSize:   4,
+		bitsPerChar: uint(bits.Len(uint(n)) - 1),
+		padding:     &paddingCache{},
+	}
+
+	blockBits := lcm(int(e.bitsPerChar), 8)
+	e.blockBytes = blockBits / 8
+	e.blockChars = blockBits / int(e.bitsPerChar)
+
+	e.digitMap = make(map[string]uint8, n)
+	for i := 0; i < n; i++ {
+		e.digitMap[alphabet[i:i+1]] = uint8(i)
+	}
+
+	e.encodeMasks = make([]uint8, 8)
+	for m := 0; m < 8; m++ {
+		e.encodeMasks[m] = 0xff >> uint(m)
+	}
+
+	k := int(e.bitsPerChar)
+	if k > 1 {
+		e.decodeMasks = make([]uint8, k-1)
+		for i := range e.decodeMasks {
+			e.decodeMasks[i] = uint8(1<<uint(i+1)) - 1
+		}
+	}
+
+	e.compileRegexes()
+
+	return e
+}
+
+// WithSeparator returns a copy of e that groups encoded characters with sep
+// instead of the default hyphen.
+func (e *Encoding) WithSeparator(sep rune) *Encoding {
+	c := e.clone()
+	c.separator = sep
+	c.compileRegexes()
+
+	return c
+}
+
+// WithGroupSize returns a copy of e that inserts a separator every n
+// characters instead of the default 4.
+func (e *Encoding) WithGroupSize(n int) *Encoding {
+	if n < 1 {
+		panic("bfh: group size must be at least 1")
+	}
+
+	c := e.clone()
+	c.groupSize = n
+	c.compileRegexes()
+
+	return c
+}
+
+// WithStrict returns a copy of e whose Encode and Decode behave like
+// EncodeStrict and DecodeStrict: no padding prefix is written or expected,
+// and the input or encoded string must already be block-aligned.
+func (e *Encoding) WithStrict(strict bool) *Encoding {
+	c := e.clone()
+	c.strict = strict
+
+	return c
+}
+
+// clone returns a shallow copy of e with its own padding cache, so that
+// lazily-computed padding sets for one Encoding are never shared with (or
+// locked by) another.
+func (e *Encoding) clone() *Encoding {
+	c := *e
+	c.padding = &paddingCache{}
+
+	return &c
+}
+
+func (e *Encoding) compileRegexes() {
+	class := regexp.QuoteMeta(e.alphabet)
+	sep := regexp.QuoteMeta(string(e.separator))
+	paddingClass := regexp.QuoteMeta(e.alphabet[:e.blockBytes])
+
+	// A separator follows every complete group of groupSize characters
+	// except the last, whose size is only constrained to 1..groupSize
+	// (appendEncodeCore never emits a separator after the final
+	// character). groupSize doesn't generally divide blockChars evenly,
+	// so the last group is frequently shorter than a full one.
+	group := fmt.Sprintf(`(?:(?:[%s]{%d}%s)*[%s]{1,%d})?`, class, e.groupSize, sep, class, e.groupSize)
+
+	e.standardRegex = regexp.MustCompile(fmt.Sprintf(`^[%s]%s%s$`, paddingClass, sep, group))
+	e.strictRegex = regexp.MustCompile(fmt.Sprintf(`^%s$`, group))
+
+	// The acceptable (hyphen-free) form has no grouping to speak of; its
+	// only structural constraint is that the data characters form whole
+	// blocks, which is what the padding prefix describes.
+	e.acceptableRegex = regexp.MustCompile(fmt.Sprintf(`^[%s](?:[%s]{%d})*$`, paddingClass, class, e.blockChars))
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+func lcm(a, b int) int {
+	return a / gcd(a, b) * b
+}
+
+// EncodedLen returns the length of the string produced by encoding n bytes
+// of input, including the padding-count prefix and group separators (or,
+// for a strict Encoding, without either).
+func (e *Encoding) EncodedLen(n int) int {
+	if e.strict {
+		return e.strictEncodedLen(n)
+	}
+
+	pad := (e.blockBytes - n%e.blockBytes) % e.blockBytes
+
+	return 2 + e.strictEncodedLen(n+pad) // +2 for the padding digit and its separator
+}
+
+// strictEncodedLen returns the length of the digits and separators alone,
+// for n bytes of already block-aligned input.
+func (e *Encoding) strictEncodedLen(n int) int {
+	charCount := n * 8 / int(e.bitsPerChar)
+
+	sepCount := 0
+	if charCount > 0 {
+		sepCount = (charCount - 1) / e.groupSize
+	}
+
+	return charCount + sepCount
+}
+
+// DecodedLen returns the maximum length in bytes of the data decoded from a
+// string with n data characters, i.e. with any padding prefix and group
+// separators already removed.
+func (e *Encoding) DecodedLen(n int) int {
+	return n * int(e.bitsPerChar) / 8
+}
+
+// Encode encodes binary data into a human readable string.
+func (e *Encoding) Encode(b []byte) (string, error) {
+	if b == nil {
+		return "", errors.New(errMsgBinaryDataMustNotBeNil)
+	}
+
+	if e.strict {
+		return e.EncodeStrict(b)
+	}
+
+	return string(e.AppendEncode(make([]byte, 0, e.EncodedLen(len(b))), b)), nil
+}
+
+// EncodeStrict encodes binary data with a length dividable by the
+// encoding's block size into a simplified human readable string.
+func (e *Encoding) EncodeStrict(b []byte) (string, error) {
+	if b == nil {
+		return "", errors.New(errMsgBinaryDataMustNotBeNil)
+	}
+
+	if len(b)%e.blockBytes != 0 {
+		return "", errors.New(errMsgStrictMustBeDividableBy5)
+	}
+
+	dst := e.appendEncodeCore(make([]byte, 0, e.strictEncodedLen(len(b))), b)
+
+	return string(dst), nil
+}
+
+// AppendEncode appends the encoding of src to dst and returns the extended
+// slice, indexing directly into it rather than building the result through
+// a bytes.Buffer.
+func (e *Encoding) AppendEncode(dst, src []byte) []byte {
+	if e.strict {
+		return e.appendEncodeCore(dst, src)
+	}
+
+	pad := (e.blockBytes - len(src)%e.blockBytes) % e.blockBytes
+
+	dst = append(dst, e.alphabet[pad])
+	dst = append(dst, string(e.separator)...)
+
+	if pad == 0 {
+		return e.appendEncodeCore(dst, src)
+	}
+
+	padded := make([]byte, len(src)+pad)
+	copy(padded, src)
+
+	return e.appendEncodeCore(dst, padded)
+}
+
+// appendEncodeCore appends the digits and separators for already
+// block-aligned data to dst.
+func (e *Encoding) appendEncodeCore(dst, data []byte) []byte {
+	charCount := 0
+	before := len(dst)
+
+	dst = e.appendEncodeChars(dst, data, &charCount)
+
+	// appendEncodeChars always separates on a group boundary, including
+	// after the very last character; trim that one back off here since
+	// there's no more data to follow it.
+	if charCount > 0 && charCount%e.groupSize == 0 && len(dst) > before {
+		dst = dst[:len(dst)-len(string(e.separator))]
+	}
+
+	return dst
+}
+
+// appendEncodeChars appends the digits for data to dst, inserting a
+// separator after every e.groupSize-th character including the last (unlike
+// appendEncodeCore); that is the right behavior for a streaming encoder that
+// doesn't yet know whether more data is coming. *charCount carries the
+// running character count across calls so grouping stays correct across
+// multiple appendEncodeChars calls on the same logical stream.
+func (e *Encoding) appendEncodeChars(dst, data []byte, charCount *int) []byte {
+	k := int(e.bitsPerChar)
+	maxBits := len(data) * 8
+
+	for bitOffset := 0; bitOffset < maxBits; bitOffset += k {
+		v := e.charAt(data, bitOffset)
+
+		dst = append(dst, e.alphabet[v])
+		*charCount++
+
+		if *charCount%e.groupSize == 0 {
+			dst = append(dst, string(e.separator)...)
+		}
+	}
+
+	return dst
+}
+
+// charAt returns the bitsPerChar-bit value starting at bitOffset within b,
+// treating b as a big-endian bit stream and zero-padding past its end.
+func (e *Encoding) charAt(b []byte, bitOffset int) uint8 {
+	k := int(e.bitsPerChar)
+	byteIndex := bitOffset / 8
+	m := bitOffset % 8
+	avail := 8 - m
+
+	first := b[byteIndex] & e.encodeMasks[m]
+
+	if avail >= k {
+		return first >> uint(avail-k)
+	}
+
+	need := k - avail
+
+	var second byte
+	if len(b) > byteIndex+1 {
+		second = b[byteIndex+1] >> uint(8-need)
+	}
+
+	return (first << uint(need)) | second
+}
+
+// Decode decodes a human readable string into binary data.
+func (e *Encoding) Decode(str string) ([]byte, error) {
+	return e.AppendDecode(nil, []byte(str))
+}
+
+// DecodeStrict decodes a string into binary data without using any padding.
+func (e *Encoding) DecodeStrict(str string) ([]byte, error) {
+	if !e.IsStrictBfh(str) {
+		return nil, errors.New(errMsgStrictInvalid)
+	}
+
+	stripped := strings.Replace(str, string(e.separator), "", -1)
+
+	return e.appendDecodeCore(nil, stripped)
+}
+
+// AppendDecode appends the decoding of src to dst and returns the extended
+// slice and any error encountered, indexing directly into dst rather than
+// building the result through a bytes.Buffer.
+func (e *Encoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	str := strings.Replace(string(src), string(e.separator), "", -1)
+
+	if e.strict {
+		if len(str)%e.blockChars != 0 {
+			return dst, errors.New(errMsgStrictMustBeDividableBy8)
+		}
+
+		return e.appendDecodeCore(dst, str)
+	}
+
+	if len(str) == 0 {
+		return dst, errors.New(errMsgPaddingNotBetween0and4)
+	}
+
+	padding, ok := e.digitMap[str[0:1]]
+	if !ok || int(padding) >= e.blockBytes {
+		return dst, errors.New(errMsgPaddingNotBetween0and4)
+	}
+
+	str = str[1:]
+
+	if len(str)%e.blockChars != 0 {
+		return dst, errors.New(errMsgStrictMustBeDividableBy8)
+	}
+
+	out, err := e.appendDecodeCore(dst, str)
+	if err != nil {
+		return dst, err
+	}
+
+	if padding > 0 {
+		out = out[:len(out)-int(padding)]
+	}
+
+	return out, nil
+}
+
+// appendDecodeCore appends the bytes decoded from str (with any padding
+// prefix and separators already removed) to dst.
+func (e *Encoding) appendDecodeCore(dst []byte, str string) ([]byte, error) {
+	k := int(e.bitsPerChar)
+	base := len(dst)
+	dst = append(dst, make([]byte, len(str)*k/8)...)
+
+	for i := 0; i < len(str); i++ {
+		charValue, ok := e.digitMap[str[i:i+1]]
+		if !ok {
+			return dst[:base], fmt.Errorf(errMsgContainsInvalidCharacter, str[i:i+1])
+		}
+
+		byteIndex := base + i*k/8
+
+		first, second := e.splitChar(charValue, i)
+
+		dst[byteIndex] |= first
+
+		if second > 0 && byteIndex+1 < len(dst) {
+			dst[byteIndex+1] |= second
+		}
+	}
+
+	return dst, nil
+}
+
+// splitChar returns the bits a decoded character contributes to its byte
+// (and, if it straddles a byte boundary, the following byte).
+func (e *Encoding) splitChar(charValue uint8, charIndex int) (byte, byte) {
+	k := int(e.bitsPerChar)
+	mod := (charIndex * k) % 8
+	avail := 8 - mod
+
+	if avail >= k {
+		return charValue << uint(avail-k), 0
+	}
+
+	need := k - avail
+
+	return charValue >> uint(need), (charValue & e.decodeMasks[need-1]) << uint(8-need)
+}
+
+// IsWellFormattedBfh returns true if str is a well-formatted string for e.
+func (e *Encoding) IsWellFormattedBfh(str string) bool {
+	if !e.standardRegex.MatchString(str) {
+		return false
+	}
+
+	stripped := strings.Replace(str, string(e.separator), "", -1)
+
+	return e.isPaddingCorrect(stripped)
+}
+
+// IsAcceptableBfh returns true if e can accept str for decoding.
+func (e *Encoding) IsAcceptableBfh(str string) bool {
+	fixedStr := strings.Replace(str, string(e.separator), "", -1)
+
+	if !e.acceptableRegex.MatchString(fixedStr) {
+		return false
+	}
+
+	return e.isPaddingCorrect(fixedStr)
+}
+
+// IsStrictBfh returns true if str is strict-compatible with e.
+func (e *Encoding) IsStrictBfh(str string) bool {
+	return e.strictRegex.MatchString(str)
+}
+
+// isPaddingCorrect reports whether the hyphen-stripped, padding-prefixed
+// string str has zero bytes in exactly the positions implied by its
+// padding-count prefix.
+func (e *Encoding) isPaddingCorrect(str string) bool {
+	l := len(str)
+	zero := e.alphabet[0:1]
+
+	if l == 1 && str == zero {
+		return true
+	}
+
+	if l < e.blockChars+1 {
+		return false
+	}
+
+	padding, ok := e.digitMap[str[0:1]]
+	if !ok {
+		return false
+	}
+
+	n := int(padding)
+	if n == 0 {
+		return true
+	}
+
+	if n >= e.blockBytes {
+		return false
+	}
+
+	bitOffset := (e.blockBytes - n) * 8
+	charIndex := bitOffset / int(e.bitsPerChar)
+	zeroChars := e.blockChars - charIndex - 1
+
+	if l < zeroChars+1 {
+		return false
+	}
+
+	for i := 0; i < zeroChars; i++ {
+		if str[l-1-i:l-i] != zero {
+			return false
+		}
+	}
+
+	checkPos := l - 1 - zeroChars
+
+	_, ok = e.paddingSet(n)[str[checkPos:checkPos+1]]
+
+	return ok
+}
+
+// paddingSet returns the set of characters allowed immediately before the
+// trailing run of zero characters for a block with n bytes of padding,
+// computing it on first use.
+func (e *Encoding) paddingSet(n int) map[string]struct{} {
+	e.padding.mu.Lock()
+	defer e.padding.mu.Unlock()
+
+	if e.padding.sets == nil {
+		e.padding.sets = make(map[int]map[string]struct{})
+	}
+
+	set, ok := e.padding.sets[n]
+	if !ok {
+		set = e.buildPaddingSet(n)
+		e.padding.sets[n] = set
+	}
+
+	return set
+}
+
+func (e *Encoding) buildPaddingSet(n int) map[string]struct{} {
+	bitOffset := (e.blockBytes - n) * 8
+	rem := bitOffset % int(e.bitsPerChar)
+	trailingBits := (int(e.bitsPerChar) - rem) % int(e.bitsPerChar)
+	mask := uint8(1<<uint(trailingBits)) - 1
+
+	set := make(map[string]struct{})
+
+	for i := 0; i < len(e.alphabet); i++ {
+		if uint8(i)&mask == 0 {
+			set[e.alphabet[i:i+1]] = struct{}{}
+		}
+	}
+
+	return set
+}
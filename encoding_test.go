@@ -0,0 +1,230 @@
+package bfh
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func testPayloads() [][]byte {
+	var payloads [][]byte
+
+	for n := 0; n <= 20; n++ {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte((i*37 + n*7) % 256)
+		}
+
+		payloads = append(payloads, b)
+	}
+
+	return payloads
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	for _, in := range testPayloads() {
+		str, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		if !IsWellFormattedBfh(str) {
+			t.Errorf("IsWellFormattedBfh(%q) = false for Encode(%v)", str, in)
+		}
+
+		if !IsAcceptableBfh(str) {
+			t.Errorf("IsAcceptableBfh(%q) = false for Encode(%v)", str, in)
+		}
+
+		out, err := Decode(str)
+		if err != nil {
+			t.Fatalf("Decode(%q) returned error: %v", str, err)
+		}
+
+		if !bytes.Equal(in, out) {
+			t.Errorf("round trip mismatch: Decode(Encode(%v)) = %v", in, out)
+		}
+	}
+}
+
+func TestEncodeEmptyNotNil(t *testing.T) {
+	str, err := Encode([]byte{})
+	if err != nil {
+		t.Fatalf("Encode([]byte{}) returned error: %v", err)
+	}
+
+	if str != "0-" {
+		t.Errorf("Encode([]byte{}) = %q, want %q", str, "0-")
+	}
+}
+
+func TestEncodeNil(t *testing.T) {
+	if _, err := Encode(nil); err == nil {
+		t.Error("Encode(nil) returned no error")
+	}
+}
+
+func TestEncodeStrictDecodeStrictRoundTrip(t *testing.T) {
+	for n := 0; n <= 25; n += 5 {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i * 13)
+		}
+
+		str, err := EncodeStrict(b)
+		if err != nil {
+			t.Fatalf("EncodeStrict(%v) returned error: %v", b, err)
+		}
+
+		if !IsStrictBfh(str) {
+			t.Errorf("IsStrictBfh(%q) = false for EncodeStrict(%v)", str, b)
+		}
+
+		out, err := DecodeStrict(str)
+		if err != nil {
+			t.Fatalf("DecodeStrict(%q) returned error: %v", str, err)
+		}
+
+		if !bytes.Equal(b, out) {
+			t.Errorf("round trip mismatch: DecodeStrict(EncodeStrict(%v)) = %v", b, out)
+		}
+	}
+
+	if _, err := EncodeStrict([]byte{1, 2, 3}); err == nil {
+		t.Error("EncodeStrict with a non-block-aligned length returned no error")
+	}
+}
+
+func TestNewEncodingPanicsOnBadAlphabet(t *testing.T) {
+	for _, alphabet := range []string{"", "a", "abc"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewEncoding(%q) did not panic", alphabet)
+				}
+			}()
+
+			NewEncoding(alphabet)
+		}()
+	}
+}
+
+func TestCustomEncodingRoundTrip(t *testing.T) {
+	enc := NewEncoding("01234567").WithGroupSize(3).WithSeparator('.')
+
+	for n := 0; n <= 12; n++ {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte((i*11 + 1) % 256)
+		}
+
+		str, err := enc.Encode(b)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", b, err)
+		}
+
+		if !enc.IsWellFormattedBfh(str) {
+			t.Errorf("IsWellFormattedBfh(%q) = false for Encode(%v)", str, b)
+		}
+
+		out, err := enc.Decode(str)
+		if err != nil {
+			t.Fatalf("Decode(%q) returned error: %v", str, err)
+		}
+
+		if !bytes.Equal(b, out) {
+			t.Errorf("round trip mismatch: Decode(Encode(%v)) = %v, got %q", b, out, str)
+		}
+	}
+}
+
+// TestPaddingSetConcurrentAccess exercises the lazily computed paddingSet
+// cache from multiple goroutines at once, on a freshly constructed Encoding
+// so the cache starts empty every time. Run with -race to confirm the
+// lock actually guards the first populate.
+func TestPaddingSetConcurrentAccess(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		enc := NewEncoding(digits)
+
+		var wg sync.WaitGroup
+
+		for n := 1; n <= 4; n++ {
+			b := make([]byte, 5-n)
+			for j := range b {
+				b[j] = byte(j + 1)
+			}
+
+			str, err := enc.Encode(b)
+			if err != nil {
+				t.Fatalf("Encode(%v) returned error: %v", b, err)
+			}
+
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+				enc.IsWellFormattedBfh(str)
+			}()
+
+			go func() {
+				defer wg.Done()
+				enc.IsAcceptableBfh(str)
+			}()
+		}
+
+		wg.Wait()
+	}
+}
+
+// TestIsWellFormattedRejectsWrongPaddingDigit checks that a string claiming
+// more padding than its data actually has is rejected rather than silently
+// accepted.
+func TestIsWellFormattedRejectsWrongPaddingDigit(t *testing.T) {
+	// 4 all-ones bytes pad to a 5-byte block with exactly 1 padding byte;
+	// the real data bits extend well past where a claim of 4 padding
+	// bytes would require zeros to start.
+	b := []byte{0xff, 0xff, 0xff, 0xff}
+
+	str, err := Encode(b)
+	if err != nil {
+		t.Fatalf("Encode(%v) returned error: %v", b, err)
+	}
+
+	if str[0] != '1' {
+		t.Fatalf("Encode(%v) = %q, want it to start with padding digit '1'", b, str)
+	}
+
+	tampered := "4" + str[1:]
+
+	if IsWellFormattedBfh(tampered) {
+		t.Errorf("IsWellFormattedBfh(%q) = true, want false: claims 4 bytes of padding but the data doesn't have that many trailing zero bits", tampered)
+	}
+
+	if IsAcceptableBfh(tampered) {
+		t.Errorf("IsAcceptableBfh(%q) = true, want false for the same reason", tampered)
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	enc := StdEncoding.WithStrict(true)
+
+	b := []byte{1, 2, 3, 4, 5}
+
+	str, err := enc.Encode(b)
+	if err != nil {
+		t.Fatalf("Encode(%v) returned error: %v", b, err)
+	}
+
+	if want := enc.EncodedLen(len(b)); len(str) != want {
+		t.Errorf("strict Encode(%v) = %q has length %d, want %d (a strict encoding carries no padding prefix)", b, str, len(str), want)
+	}
+
+	out, err := enc.Decode(str)
+	if err != nil {
+		t.Fatalf("Decode(%q) returned error: %v", str, err)
+	}
+
+	if !bytes.Equal(b, out) {
+		t.Errorf("round trip mismatch: Decode(Encode(%v)) = %v", b, out)
+	}
+}
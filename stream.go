@@ -0,0 +1,198 @@
+package bfh
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// errEncoderClosed is returned by Write once the encoder has been closed.
+var errEncoderClosed = errors.New("bfh: encoder already closed")
+
+// encoder is the io.WriteCloser returned by NewEncoder.
+type encoder struct {
+	w         io.Writer
+	pending   []byte       // < blockBytes unencoded bytes not yet forming a full block
+	buf       bytes.Buffer // digits and separators encoded from complete blocks seen so far
+	charCount int          // running character count, for correct grouping across Write calls
+	closed    bool
+	err       error
+}
+
+// NewEncoder returns a WriteCloser that encodes bytes written to it and
+// writes the resulting BFH string to w once Close is called.
+//
+// Write encodes each complete block of the encoding's block size as soon as
+// it has been seen, holding back only the last, possibly-short block, so a
+// large input is never held in memory as a whole. Because the BFH format
+// stores the padding count in the very first character of the output,
+// though, the encoded digits themselves can only be written to w once Close
+// determines the padding, so they're accumulated in an internal buffer
+// until then.
+func NewEncoder(w io.Writer) io.WriteCloser {
+	return &encoder{w: w}
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	if e.closed {
+		return 0, errEncoderClosed
+	}
+
+	e.pending = append(e.pending, p...)
+
+	full := (len(e.pending) / StdEncoding.blockBytes) * StdEncoding.blockBytes
+	if full > 0 {
+		e.buf.Write(StdEncoding.appendEncodeChars(nil, e.pending[:full], &e.charCount))
+		e.pending = append([]byte(nil), e.pending[full:]...)
+	}
+
+	return len(p), nil
+}
+
+func (e *encoder) Close() error {
+	if e.closed {
+		return e.err
+	}
+
+	e.closed = true
+
+	if e.err != nil {
+		return e.err
+	}
+
+	pad := (StdEncoding.blockBytes - len(e.pending)%StdEncoding.blockBytes) % StdEncoding.blockBytes
+
+	final := e.pending
+	if pad > 0 {
+		final = make([]byte, len(e.pending)+pad)
+		copy(final, e.pending)
+	}
+
+	e.buf.Write(StdEncoding.appendEncodeChars(nil, final, &e.charCount))
+
+	body := e.buf.Bytes()
+	if e.charCount > 0 && e.charCount%StdEncoding.groupSize == 0 {
+		body = body[:len(body)-len(string(StdEncoding.separator))]
+	}
+
+	out := make([]byte, 0, 2+len(body))
+	out = append(out, StdEncoding.alphabet[pad])
+	out = append(out, string(StdEncoding.separator)...)
+	out = append(out, body...)
+
+	_, err := e.w.Write(out)
+	if err != nil {
+		e.err = err
+	}
+
+	return err
+}
+
+// decoder is the io.Reader returned by NewDecoder.
+type decoder struct {
+	r         *bufio.Reader
+	sawHeader bool
+	padding   uint8
+	chars     bytes.Buffer // digits read since the last decoded group
+	held      []byte       // most recently decoded group, released once we know it isn't the last
+	out       bytes.Buffer // decoded bytes ready to be handed out by Read
+	err       error
+}
+
+// NewDecoder returns a Reader that decodes the BFH string read from r. The
+// padding prefix is consumed lazily from the first bytes read, hyphens are
+// stripped as they're encountered, and decoded bytes are produced a group
+// at a time rather than buffering the whole input.
+func NewDecoder(r io.Reader) io.Reader {
+	return &decoder{r: bufio.NewReader(r)}
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	for d.out.Len() == 0 && d.err == nil {
+		d.fill()
+	}
+
+	if d.out.Len() == 0 {
+		return 0, d.err
+	}
+
+	return d.out.Read(p)
+}
+
+// fill decodes at most one more group of 8 digits into d.out, reading from
+// d.r as needed, or records d.err once the input is exhausted or invalid.
+func (d *decoder) fill() {
+	if !d.sawHeader {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			d.err = err
+			return
+		}
+
+		padding, ok := StdEncoding.digitMap[string([]byte{b})]
+		if !ok || int(padding) >= StdEncoding.blockBytes {
+			d.err = errors.New(errMsgPaddingNotBetween0and4)
+			return
+		}
+
+		d.padding = padding
+		d.sawHeader = true
+	}
+
+	for d.chars.Len() < StdEncoding.blockChars {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				d.finish()
+				return
+			}
+
+			d.err = err
+			return
+		}
+
+		if b == '-' {
+			continue
+		}
+
+		d.chars.WriteByte(b)
+	}
+
+	group, err := StdEncoding.appendDecodeCore(nil, d.chars.String())
+	if err != nil {
+		d.err = err
+		return
+	}
+
+	d.chars.Reset()
+	d.out.Write(d.held)
+	d.held = group
+}
+
+// finish is called once the underlying reader is exhausted: the caller's
+// loop in fill only reaches EOF here while d.chars holds fewer than a full
+// block's worth of digits, so any leftover digits mean the string was
+// truncated mid-block. It strips the padding from the held final group and
+// records io.EOF.
+func (d *decoder) finish() {
+	final := d.held
+	d.held = nil
+
+	if d.chars.Len() > 0 {
+		d.err = errors.New(errMsgStrictMustBeDividableBy8)
+		return
+	}
+
+	if int(d.padding) > len(final) {
+		d.err = errors.New(errMsgStrictMustBeDividableBy8)
+		return
+	}
+
+	d.out.Write(final[:len(final)-int(d.padding)])
+	d.err = io.EOF
+}
@@ -0,0 +1,144 @@
+package bfh
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func streamEncode(t *testing.T, data []byte, writeSize int) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+
+	for i := 0; i < len(data); i += writeSize {
+		end := i + writeSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		if _, err := enc.Write(data[i:end]); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestEncoderMatchesEncode(t *testing.T) {
+	for _, in := range testPayloads() {
+		want, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		for _, writeSize := range []int{1, 2, 3, len(in) + 1} {
+			got := streamEncode(t, in, writeSize)
+			if got != want {
+				t.Errorf("streamEncode(%v, writeSize=%d) = %q, want %q", in, writeSize, got, want)
+			}
+		}
+	}
+}
+
+func TestEncoderCloseWithoutWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	if buf.String() != "0-" {
+		t.Errorf("Close of a fresh encoder wrote %q, want %q", buf.String(), "0-")
+	}
+}
+
+func TestEncoderWriteAfterClose(t *testing.T) {
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	if _, err := enc.Write([]byte{1}); err == nil {
+		t.Error("Write after Close returned no error")
+	}
+}
+
+func TestDecoderMatchesDecode(t *testing.T) {
+	for _, in := range testPayloads() {
+		str, err := Encode(in)
+		if err != nil {
+			t.Fatalf("Encode(%v) returned error: %v", in, err)
+		}
+
+		dec := NewDecoder(bytes.NewReader([]byte(str)))
+
+		out, err := io.ReadAll(dec)
+		if err != nil {
+			t.Fatalf("ReadAll(NewDecoder(%q)) returned error: %v", str, err)
+		}
+
+		if !bytes.Equal(in, out) {
+			t.Errorf("streamed decode of %q = %v, want %v", str, out, in)
+		}
+	}
+}
+
+func TestDecoderInvalidCharacterMidGroup(t *testing.T) {
+	str, err := Encode([]byte{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	// Corrupt a data character in the middle of the first group with one
+	// that isn't in the alphabet and isn't the separator.
+	bad := []byte(str)
+	bad[3] = '!'
+
+	_, err = io.ReadAll(NewDecoder(bytes.NewReader(bad)))
+	if err == nil || !strings.Contains(err.Error(), "invalid character") {
+		t.Errorf("ReadAll(NewDecoder(%q)) returned error %v, want an invalid-character error", bad, err)
+	}
+}
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	for _, in := range testPayloads() {
+		var buf bytes.Buffer
+
+		enc := NewEncoder(&buf)
+
+		for i := 0; i < len(in); i += 3 {
+			end := i + 3
+			if end > len(in) {
+				end = len(in)
+			}
+
+			if _, err := enc.Write(in[i:end]); err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+		}
+
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close returned error: %v", err)
+		}
+
+		out, err := io.ReadAll(NewDecoder(&buf))
+		if err != nil {
+			t.Fatalf("ReadAll returned error: %v", err)
+		}
+
+		if !bytes.Equal(in, out) {
+			t.Errorf("round trip mismatch for %v: got %v", in, out)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bfh
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// BFH is a byte slice that serializes itself as a StdEncoding string rather
+// than raw bytes, so values such as UUIDs or hashes read as human-friendly
+// BFH strings in JSON, logs and SQL columns instead of requiring manual
+// Encode/Decode calls at every boundary.
+type BFH []byte
+
+// String returns the StdEncoding encoding of b, or "" if it can't be
+// encoded.
+func (b BFH) String() string {
+	text, err := b.MarshalText()
+	if err != nil {
+		return ""
+	}
+
+	return string(text)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b BFH) MarshalText() ([]byte, error) {
+	data := []byte(b)
+	if data == nil {
+		data = []byte{}
+	}
+
+	str, err := Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(str), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *BFH) UnmarshalText(text []byte) error {
+	data, err := Decode(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = data
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, returning the raw
+// bytes of b.
+func (b BFH) MarshalBinary() ([]byte, error) {
+	return append([]byte(nil), b...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (b *BFH) UnmarshalBinary(data []byte) error {
+	*b = append([]byte(nil), data...)
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding b as a JSON string holding
+// its StdEncoding form.
+func (b BFH) MarshalJSON() ([]byte, error) {
+	text, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BFH) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*b = nil
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	return b.UnmarshalText([]byte(str))
+}
+
+// Value implements driver.Valuer, storing b as its StdEncoding form.
+func (b BFH) Value() (driver.Value, error) {
+	text, err := b.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner, accepting a BFH string as either a string or
+// []byte column value.
+func (b *BFH) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*b = nil
+		return nil
+	case string:
+		return b.UnmarshalText([]byte(v))
+	case []byte:
+		return b.UnmarshalText(v)
+	default:
+		return fmt.Errorf("bfh: cannot scan %T into BFH", src)
+	}
+}
@@ -0,0 +1,135 @@
+package bfh
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBFHTextRoundTrip(t *testing.T) {
+	b := BFH{1, 2, 3, 4, 5}
+
+	text, err := b.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText returned error: %v", err)
+	}
+
+	var out BFH
+
+	if err := out.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText(%q) returned error: %v", text, err)
+	}
+
+	if !bytes.Equal(b, out) {
+		t.Errorf("round trip mismatch: got %v, want %v", out, b)
+	}
+
+	if got, want := b.String(), string(text); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBFHBinaryRoundTrip(t *testing.T) {
+	b := BFH{1, 2, 3, 4, 5}
+
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned error: %v", err)
+	}
+
+	var out BFH
+
+	if err := out.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary returned error: %v", err)
+	}
+
+	if !bytes.Equal(b, out) {
+		t.Errorf("round trip mismatch: got %v, want %v", out, b)
+	}
+}
+
+func TestBFHJSONRoundTrip(t *testing.T) {
+	b := BFH{1, 2, 3, 4, 5}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %v", err)
+	}
+
+	var out BFH
+
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("json.Unmarshal(%s) returned error: %v", data, err)
+	}
+
+	if !bytes.Equal(b, out) {
+		t.Errorf("round trip mismatch: got %v, want %v", out, b)
+	}
+}
+
+func TestBFHJSONNull(t *testing.T) {
+	var out BFH = BFH{1, 2, 3}
+
+	if err := json.Unmarshal([]byte("null"), &out); err != nil {
+		t.Fatalf("json.Unmarshal(null) returned error: %v", err)
+	}
+
+	if out != nil {
+		t.Errorf("Unmarshal(null) = %v, want nil", out)
+	}
+}
+
+func TestBFHValue(t *testing.T) {
+	b := BFH{1, 2, 3, 4, 5}
+
+	v, err := b.Value()
+	if err != nil {
+		t.Fatalf("Value returned error: %v", err)
+	}
+
+	str, ok := v.(string)
+	if !ok {
+		t.Fatalf("Value() returned %T, want string", v)
+	}
+
+	if str != b.String() {
+		t.Errorf("Value() = %q, want %q", str, b.String())
+	}
+}
+
+func TestBFHScan(t *testing.T) {
+	b := BFH{1, 2, 3, 4, 5}
+	str := b.String()
+
+	var fromString BFH
+	if err := fromString.Scan(str); err != nil {
+		t.Fatalf("Scan(string) returned error: %v", err)
+	}
+
+	if !bytes.Equal(b, fromString) {
+		t.Errorf("Scan(%q) = %v, want %v", str, fromString, b)
+	}
+
+	var fromBytes BFH
+	if err := fromBytes.Scan([]byte(str)); err != nil {
+		t.Fatalf("Scan([]byte) returned error: %v", err)
+	}
+
+	if !bytes.Equal(b, fromBytes) {
+		t.Errorf("Scan(%q) = %v, want %v", str, fromBytes, b)
+	}
+
+	withValue := BFH{9, 9, 9}
+	if err := withValue.Scan(nil); err != nil {
+		t.Fatalf("Scan(nil) returned error: %v", err)
+	}
+
+	if withValue != nil {
+		t.Errorf("Scan(nil) = %v, want nil", withValue)
+	}
+
+	var fromInvalid BFH
+	if err := fromInvalid.Scan(42); err == nil {
+		t.Error("Scan(42) returned no error")
+	}
+}